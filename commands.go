@@ -0,0 +1,152 @@
+// mautrix-slack - A Matrix-Slack puppeting bridge.
+// Copyright (C) 2022 Tulir Asokan
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"maunium.net/go/mautrix/bridge/commands"
+)
+
+var HelpSectionAuth = commands.HelpSection{Name: "Authentication", Order: 10}
+
+type WrappedCommandEvent struct {
+	*commands.Event
+	Bridge *SlackBridge
+	User   *User
+}
+
+func wrapCommand(handler func(*WrappedCommandEvent)) func(*commands.Event) {
+	return func(ce *commands.Event) {
+		handler(&WrappedCommandEvent{ce, ce.Bridge.Child.(*SlackBridge), ce.User.(*User)})
+	}
+}
+
+// cmdLoginToken logs a user in with a pre-minted session token instead of
+// the interactive login flow, optionally pairing it with an app-level
+// token so Connect uses the socket mode backend instead of legacy RTM.
+var cmdLoginToken = &commands.FullHandler{
+	Func: wrapCommand(fnLoginToken),
+	Name: "login-token",
+	Help: commands.HelpMeta{
+		Section:     HelpSectionAuth,
+		Description: "Log into Slack with an existing xoxc/xoxp session token, optionally with an app-level token for socket mode.",
+		Args:        "<_token_> [_app token_]",
+	},
+}
+
+func fnLoginToken(ce *WrappedCommandEvent) {
+	if len(ce.Args) < 1 {
+		ce.Reply("**Usage:** `login-token <xoxc/xoxp token> [xapp-... app token]`")
+
+		return
+	}
+
+	ce.User.Token = ce.Args[0]
+	if len(ce.Args) > 1 {
+		ce.User.AppToken = ce.Args[1]
+	}
+	ce.User.Update()
+
+	if err := ce.User.Connect(); err != nil {
+		ce.Reply("Failed to connect to Slack: %v", err)
+
+		return
+	}
+
+	ce.Reply("Successfully logged into Slack")
+}
+
+var HelpSectionSettings = commands.HelpSection{Name: "Settings", Order: 20}
+
+var cmdToggle = &commands.FullHandler{
+	Func: wrapCommand(fnToggle),
+	Name: "toggle",
+	Help: commands.HelpMeta{
+		Section:     HelpSectionSettings,
+		Description: "Toggle a per-user bridging preference.",
+		Args:        "<receipts|presence|typing>",
+	},
+	RequiresLogin: true,
+}
+
+func fnToggle(ce *WrappedCommandEvent) {
+	if len(ce.Args) != 1 {
+		ce.Reply("**Usage:** `toggle <receipts|presence|typing>`")
+
+		return
+	}
+
+	switch ce.Args[0] {
+	case "receipts":
+		ce.User.BridgeReceipts = !ce.User.BridgeReceipts
+		ce.Reply("Read receipt bridging is now %s", enabledString(ce.User.BridgeReceipts))
+	case "presence":
+		ce.User.BridgePresence = !ce.User.BridgePresence
+		ce.Reply("Presence bridging is now %s", enabledString(ce.User.BridgePresence))
+	case "typing":
+		ce.User.BridgeTyping = !ce.User.BridgeTyping
+		ce.Reply("Typing bridging is now %s", enabledString(ce.User.BridgeTyping))
+	default:
+		ce.Reply("Unknown setting %q, expected `receipts`, `presence` or `typing`", ce.Args[0])
+
+		return
+	}
+
+	ce.User.Update()
+}
+
+var cmdSettings = &commands.FullHandler{
+	Func: wrapCommand(fnSettings),
+	Name: "settings",
+	Help: commands.HelpMeta{
+		Section:     HelpSectionSettings,
+		Description: "View your current bridging preferences.",
+	},
+	RequiresLogin: true,
+}
+
+func fnSettings(ce *WrappedCommandEvent) {
+	ce.Reply(
+		"**Receipts:** %s\n**Presence:** %s\n**Typing:** %s",
+		enabledString(ce.User.BridgeReceipts),
+		enabledString(ce.User.BridgePresence),
+		enabledString(ce.User.BridgeTyping),
+	)
+}
+
+func enabledString(enabled bool) string {
+	if enabled {
+		return "enabled"
+	}
+
+	return "disabled"
+}
+
+// allCommands lists every command defined in this file so RegisterCommands
+// has one place to add them all to the bridge's command processor.
+var allCommands = []*commands.FullHandler{
+	cmdLoginToken,
+	cmdToggle,
+	cmdSettings,
+}
+
+// RegisterCommands adds this bridge's management room commands to the
+// command processor. Called from SlackBridge.Init.
+func (br *SlackBridge) RegisterCommands() {
+	for _, handler := range allCommands {
+		br.CommandProcessor.AddHandlers(handler)
+	}
+}
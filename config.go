@@ -0,0 +1,50 @@
+// mautrix-slack - A Matrix-Slack puppeting bridge.
+// Copyright (C) 2022 Tulir Asokan
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"maunium.net/go/mautrix/bridge/bridgeconfig"
+	"maunium.net/go/mautrix/id"
+)
+
+type BridgeConfig struct {
+	bridgeconfig.DoublePuppetConfig `yaml:",inline"`
+
+	Permissions bridgeconfig.PermissionConfig `yaml:"permissions"`
+
+	// DefaultBridgeReceipts, DefaultBridgePresence and DefaultBridgeTyping
+	// seed the per-user toggles of the same name when a user is first
+	// created; users can flip them later with `!slack toggle`.
+	DefaultBridgeReceipts bool `yaml:"default_bridge_receipts"`
+	DefaultBridgePresence bool `yaml:"default_bridge_presence"`
+	DefaultBridgeTyping   bool `yaml:"default_bridge_typing"`
+}
+
+type Config struct {
+	Bridge BridgeConfig `yaml:"bridge"`
+}
+
+// CanAutoDoublePuppet returns whether the given Matrix user can have double
+// puppeting enabled automatically, based on the configured shared secrets
+// and .well-known discovery settings.
+func (config *Config) CanAutoDoublePuppet(userID id.UserID) bool {
+	return config.Bridge.DoublePuppetConfig.CanAutoDoublePuppet(userID)
+}
+
+func (config *Config) GetDoublePuppetConfig() bridgeconfig.DoublePuppetConfig {
+	return config.Bridge.DoublePuppetConfig
+}
@@ -0,0 +1,46 @@
+// mautrix-slack - A Matrix-Slack puppeting bridge.
+// Copyright (C) 2022 Tulir Asokan
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package database
+
+import "database/sql"
+
+type upgradeFunc func(*sql.Tx, *Database) error
+
+// upgrades holds schema migrations in order, keyed by the index they run at.
+// It's appended to by each change that needs a new column; Database.Init
+// is expected to run every entry the stored schema version hasn't reached
+// yet.
+var upgrades []upgradeFunc
+
+func init() {
+	upgrades = append(upgrades, func(tx *sql.Tx, _ *Database) error {
+		_, err := tx.Exec(`ALTER TABLE "user" ADD COLUMN app_token TEXT`)
+
+		return err
+	})
+
+	upgrades = append(upgrades, func(tx *sql.Tx, _ *Database) error {
+		for _, column := range []string{"bridge_receipts", "bridge_presence", "bridge_typing"} {
+			_, err := tx.Exec(`ALTER TABLE "user" ADD COLUMN ` + column + ` BOOLEAN NOT NULL DEFAULT false`)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,135 @@
+// mautrix-slack - A Matrix-Slack puppeting bridge.
+// Copyright (C) 2022 Tulir Asokan
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"database/sql"
+
+	log "maunium.net/go/maulogger/v2"
+
+	"maunium.net/go/mautrix/id"
+)
+
+type UserQuery struct {
+	db  *Database
+	log log.Logger
+}
+
+func (uq *UserQuery) New() *User {
+	return &User{
+		db:  uq.db,
+		log: uq.log,
+	}
+}
+
+const userColumns = `mxid, management_room, id, token, app_token, bridge_receipts, bridge_presence, bridge_typing`
+
+func (uq *UserQuery) GetAll() (users []*User) {
+	rows, err := uq.db.Query(`SELECT ` + userColumns + ` FROM "user"`)
+	if err != nil || rows == nil {
+		return nil
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		users = append(users, uq.New().Scan(rows))
+	}
+
+	return
+}
+
+func (uq *UserQuery) GetByMXID(userID id.UserID) *User {
+	row := uq.db.QueryRow(`SELECT `+userColumns+` FROM "user" WHERE mxid=$1`, userID)
+	if row == nil {
+		return nil
+	}
+
+	return uq.New().Scan(row)
+}
+
+func (uq *UserQuery) GetByID(slackID string) *User {
+	row := uq.db.QueryRow(`SELECT `+userColumns+` FROM "user" WHERE id=$1`, slackID)
+	if row == nil {
+		return nil
+	}
+
+	return uq.New().Scan(row)
+}
+
+type User struct {
+	db  *Database
+	log log.Logger
+
+	MXID           id.UserID
+	ManagementRoom id.RoomID
+	ID             string
+	Token          string
+	// AppToken holds the xapp-… app-level token used to connect via Socket
+	// Mode. It's empty for users still relying on the legacy RTM backend.
+	AppToken string
+
+	BridgeReceipts bool
+	BridgePresence bool
+	BridgeTyping   bool
+}
+
+type Scannable interface {
+	Scan(...interface{}) error
+}
+
+func (user *User) Scan(row Scannable) *User {
+	var managementRoom, slackID, token, appToken sql.NullString
+
+	err := row.Scan(
+		&user.MXID, &managementRoom, &slackID, &token, &appToken,
+		&user.BridgeReceipts, &user.BridgePresence, &user.BridgeTyping,
+	)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			user.log.Errorln("Database scan failed:", err)
+		}
+
+		return nil
+	}
+
+	user.ManagementRoom = id.RoomID(managementRoom.String)
+	user.ID = slackID.String
+	user.Token = token.String
+	user.AppToken = appToken.String
+
+	return user
+}
+
+func (user *User) Insert() {
+	_, err := user.db.Exec(`INSERT INTO "user" (`+userColumns+`) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		user.MXID, user.ManagementRoom, user.ID, user.Token, user.AppToken,
+		user.BridgeReceipts, user.BridgePresence, user.BridgeTyping)
+	if err != nil {
+		user.log.Warnln("Failed to insert user:", err)
+	}
+}
+
+func (user *User) Update() {
+	_, err := user.db.Exec(
+		`UPDATE "user" SET management_room=$1, id=$2, token=$3, app_token=$4, bridge_receipts=$5, bridge_presence=$6, bridge_typing=$7 WHERE mxid=$8`,
+		user.ManagementRoom, user.ID, user.Token, user.AppToken,
+		user.BridgeReceipts, user.BridgePresence, user.BridgeTyping, user.MXID,
+	)
+	if err != nil {
+		user.log.Warnln("Failed to update user:", err)
+	}
+}
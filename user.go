@@ -17,12 +17,15 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"sync"
 
 	log "maunium.net/go/maulogger/v2"
 
 	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"github.com/slack-go/slack/socketmode"
 	"maunium.net/go/mautrix/bridge"
 	"maunium.net/go/mautrix/bridge/bridgeconfig"
 	"maunium.net/go/mautrix/id"
@@ -46,7 +49,15 @@ type User struct {
 	PermissionLevel bridgeconfig.PermissionLevel
 
 	Client *slack.Client
-	rtm    *slack.RTM
+	// rtm is set when the user is connected via the legacy RTM backend.
+	rtm *slack.RTM
+	// socket is set when the user is connected via Socket Mode instead.
+	socket *socketmode.Client
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	connected bool
 }
 
 func (user *User) GetPermissionLevel() bridgeconfig.PermissionLevel {
@@ -96,6 +107,9 @@ func (br *SlackBridge) loadUser(dbUser *database.User, mxid *id.UserID) *User {
 
 		dbUser = br.DB.User.New()
 		dbUser.MXID = *mxid
+		dbUser.BridgeReceipts = br.Config.Bridge.DefaultBridgeReceipts
+		dbUser.BridgePresence = br.Config.Bridge.DefaultBridgePresence
+		dbUser.BridgeTyping = br.Config.Bridge.DefaultBridgeTyping
 		dbUser.Insert()
 	}
 
@@ -228,21 +242,51 @@ func (user *User) tryAutomaticDoublePuppeting() {
 		return
 	}
 
-	accessToken, err := puppet.loginWithSharedSecret(user.MXID)
+	err := user.bridge.DoublePuppet.Login(user.MXID)
 	if err != nil {
-		user.log.Warnln("Failed to login with shared secret:", err)
+		user.log.Warnln("Failed to login with double puppet:", err)
 
 		return
 	}
 
-	err = puppet.SwitchCustomMXID(accessToken, user.MXID)
-	if err != nil {
-		puppet.log.Warnln("Failed to switch to auto-logined custom puppet:", err)
+	user.log.Infoln("Successfully automatically enabled custom puppet")
+}
+
+// doublePuppetIsPresent returns whether this user has an active double
+// puppet, which is a prerequisite for forwarding Slack receipts/presence to
+// Matrix as that user rather than as the bridge bot.
+func (user *User) doublePuppetIsPresent() bool {
+	puppet := user.bridge.GetPuppetByCustomMXID(user.MXID)
+
+	return puppet != nil && puppet.CustomIntent() != nil
+}
+
+// shouldBridgeReceipts is Matrix→Slack: marking a Slack conversation read
+// uses the user's own Client/token, not a double puppet, so it only depends
+// on the toggle.
+func (user *User) shouldBridgeReceipts() bool {
+	return user.BridgeReceipts
+}
+
+func (user *User) shouldBridgePresence() bool {
+	return user.BridgePresence && user.doublePuppetIsPresent()
+}
 
+// HandleMatrixReceipt bridges a Matrix read receipt to Slack by marking the
+// conversation read up to the given message timestamp, honoring the user's
+// receipts toggle. It's called by the portal's Matrix receipt handler.
+func (user *User) HandleMatrixReceipt(channelID, timestamp string) {
+	if !user.shouldBridgeReceipts() {
 		return
 	}
 
-	user.log.Infoln("Successfully automatically enabled custom puppet")
+	if user.Client == nil {
+		return
+	}
+
+	if err := user.Client.MarkConversation(channelID, timestamp); err != nil {
+		user.log.Warnln("Failed to mark Slack conversation as read:", err)
+	}
 }
 
 func (user *User) syncChatDoublePuppetDetails(portal *Portal, justCreated bool) {
@@ -281,9 +325,9 @@ func (user *User) Logout() error {
 
 	puppet := user.bridge.GetPuppetByID(user.ID)
 	if puppet.CustomMXID != "" {
-		err := puppet.SwitchCustomMXID("", "")
+		err := puppet.ClearCustomMXID()
 		if err != nil {
-			user.log.Warnln("Failed to logout-matrix while logging out of Discord:", err)
+			user.log.Warnln("Failed to logout-matrix while logging out of Slack:", err)
 		}
 	}
 
@@ -292,7 +336,7 @@ func (user *User) Logout() error {
 	// 	return err
 	// }
 
-	user.Client = nil
+	user.teardownConnection()
 
 	user.Token = ""
 	user.Update()
@@ -304,9 +348,19 @@ func (user *User) Connected() bool {
 	user.Lock()
 	defer user.Unlock()
 
-	return user.Client != nil
+	return user.connected
+}
+
+func (user *User) setConnected(connected bool) {
+	user.Lock()
+	defer user.Unlock()
+
+	user.connected = connected
 }
 
+// Connect picks a connection backend based on the credentials stored on the
+// user: if an app-level token (xapp-…) is present, it connects via Socket
+// Mode, otherwise it falls back to the legacy RTM API.
 func (user *User) Connect() error {
 	user.Lock()
 	defer user.Unlock()
@@ -315,11 +369,40 @@ func (user *User) Connect() error {
 		return ErrNotLoggedIn
 	}
 
-	user.log.Debugln("connecting to slack")
+	if user.Client != nil {
+		user.teardownConnection()
+	}
+
+	user.ctx, user.cancel = context.WithCancel(context.Background())
+
+	if user.AppToken != "" {
+		return user.connectSocketMode()
+	}
+
+	return user.connectRTM()
+}
+
+func (user *User) connectRTM() error {
+	user.log.Debugln("connecting to slack via RTM")
 
 	user.Client = slack.New(user.Token)
 	user.rtm = user.Client.NewRTM()
 
+	go user.rtm.ManageConnection()
+	go user.rtmEventLoop(user.ctx, user.rtm)
+
+	return nil
+}
+
+func (user *User) connectSocketMode() error {
+	user.log.Debugln("connecting to slack via socket mode")
+
+	user.Client = slack.New(user.Token, slack.OptionAppLevelToken(user.AppToken))
+	user.socket = socketmode.New(user.Client)
+
+	go user.socket.RunContext(user.ctx)
+	go user.socketModeEventLoop(user.ctx, user.socket)
+
 	return nil
 }
 
@@ -331,9 +414,243 @@ func (user *User) Disconnect() error {
 		return ErrNotConnected
 	}
 
-	// TODO: cancel the rtm context
+	user.teardownConnection()
+
+	return nil
+}
+
+// teardownConnection cancels the event loop context and disconnects
+// whichever backend is active. Callers must hold user.Lock().
+func (user *User) teardownConnection() {
+	if user.cancel != nil {
+		user.cancel()
+	}
+
+	if user.rtm != nil {
+		if err := user.rtm.Disconnect(); err != nil {
+			user.log.Warnln("Error disconnecting RTM:", err)
+		}
+	}
 
 	user.Client = nil
+	user.rtm = nil
+	user.socket = nil
+	user.connected = false
+}
 
-	return nil
-}
\ No newline at end of file
+// rtmEventLoop reads events off the RTM's IncomingEvents channel and
+// dispatches them to the relevant portal/puppet handlers until ctx is
+// cancelled (via Disconnect) or the channel is closed.
+func (user *User) rtmEventLoop(ctx context.Context, rtm *slack.RTM) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case evt, ok := <-rtm.IncomingEvents:
+			if !ok {
+				return
+			}
+
+			switch data := evt.Data.(type) {
+			case *slack.ConnectedEvent:
+				user.log.Infoln("Connected to Slack RTM")
+				user.setConnected(true)
+			case *slack.DisconnectedEvent:
+				// rtm.ManageConnection already backs off and reconnects on
+				// its own; we just track state here instead of blocking
+				// this loop (which would stall every other event, including
+				// the ConnectedEvent that follows a successful reconnect).
+				user.log.Warnln("Disconnected from Slack RTM, intentional:", data.Intentional)
+				user.setConnected(false)
+			case *slack.InvalidAuthEvent:
+				user.log.Errorln("Slack RTM reported invalid auth, logging out")
+				user.setConnected(false)
+				user.handleInvalidAuth()
+
+				return
+			default:
+				user.dispatchSlackEvent(data)
+			}
+		}
+	}
+}
+
+// socketModeEventLoop is the Socket Mode equivalent of rtmEventLoop. It
+// acknowledges Events API payloads as required by Slack and feeds the
+// wrapped inner event to the same dispatchSlackEvent used by the RTM
+// backend, so portal/puppet handlers don't need to care which backend is
+// in use.
+func (user *User) socketModeEventLoop(ctx context.Context, client *socketmode.Client) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case evt, ok := <-client.Events:
+			if !ok {
+				return
+			}
+
+			switch evt.Type {
+			case socketmode.EventTypeConnecting:
+				user.log.Debugln("Connecting to Slack via socket mode")
+			case socketmode.EventTypeConnected:
+				user.log.Infoln("Connected to Slack via socket mode")
+				user.setConnected(true)
+			case socketmode.EventTypeConnectionError, socketmode.EventTypeDisconnect:
+				user.log.Warnln("Disconnected from Slack socket mode")
+				user.setConnected(false)
+			case socketmode.EventTypeInvalidAuth:
+				user.log.Errorln("Slack socket mode reported invalid auth, logging out")
+				user.setConnected(false)
+				user.handleInvalidAuth()
+
+				return
+			case socketmode.EventTypeEventsAPI:
+				eventsAPIEvent, ok := evt.Data.(slackevents.EventsAPIEvent)
+				if !ok {
+					continue
+				}
+
+				if evt.Request != nil {
+					client.Ack(*evt.Request)
+				}
+
+				user.dispatchSlackEvent(eventsAPIEvent.InnerEvent.Data)
+			default:
+				user.log.Debugfln("Unhandled socket mode event of type %s", evt.Type)
+			}
+		}
+	}
+}
+
+// SlackMessage is a backend-agnostic view of an incoming message, built from
+// either the RTM or the Events API (Socket Mode) event types so
+// portal.HandleSlackMessage only has to implement one signature. It carries
+// SubType/SubMessage/ThreadTimestamp/Files through from the original event
+// so the portal can still handle edits, deletions, threading and
+// attachments instead of only plain new messages.
+type SlackMessage struct {
+	Channel   string
+	User      string
+	Text      string
+	Timestamp string
+
+	SubType         string
+	SubMessage      *slack.Msg
+	ThreadTimestamp string
+	Files           []slack.File
+}
+
+// SlackReaction is the reaction-event equivalent of SlackMessage.
+type SlackReaction struct {
+	Channel   string
+	Timestamp string
+	User      string
+	Reaction  string
+}
+
+// dispatchSlackEvent forwards a decoded Slack event to the relevant
+// portal/puppet handler. It is shared by both connection backends: the RTM
+// loop passes RTM event types directly, while the socket mode loop unwraps
+// Events API payloads into their slackevents equivalents first. Event types
+// that differ in shape between the two backends (messages, reactions) are
+// normalized to a single struct before being handed to the portal so those
+// handlers don't need to deal with both representations.
+func (user *User) dispatchSlackEvent(data interface{}) {
+	switch evt := data.(type) {
+	case *slack.MessageEvent:
+		user.forwardMessage(&SlackMessage{
+			Channel:         evt.Channel,
+			User:            evt.User,
+			Text:            evt.Text,
+			Timestamp:       evt.Timestamp,
+			SubType:         evt.SubType,
+			SubMessage:      evt.SubMessage,
+			ThreadTimestamp: evt.ThreadTimestamp,
+			Files:           evt.Files,
+		})
+	case *slackevents.MessageEvent:
+		var subMessage *slack.Msg
+		if evt.Message != nil {
+			subMessage = &slack.Msg{
+				Type:      evt.Message.Type,
+				Channel:   evt.Message.Channel,
+				User:      evt.Message.User,
+				Text:      evt.Message.Text,
+				Timestamp: evt.Message.TimeStamp,
+				SubType:   evt.Message.SubType,
+			}
+		}
+
+		user.forwardMessage(&SlackMessage{
+			Channel:         evt.Channel,
+			User:            evt.User,
+			Text:            evt.Text,
+			Timestamp:       evt.TimeStamp,
+			SubType:         evt.SubType,
+			SubMessage:      subMessage,
+			ThreadTimestamp: evt.ThreadTimeStamp,
+			Files:           evt.Files,
+		})
+	case *slack.ReactionAddedEvent:
+		user.forwardReactionAdded(&SlackReaction{Channel: evt.Item.Channel, Timestamp: evt.Item.Timestamp, User: evt.User, Reaction: evt.Reaction})
+	case *slackevents.ReactionAddedEvent:
+		user.forwardReactionAdded(&SlackReaction{Channel: evt.Item.Channel, Timestamp: evt.Item.Timestamp, User: evt.User, Reaction: evt.Reaction})
+	case *slack.ReactionRemovedEvent:
+		user.forwardReactionRemoved(&SlackReaction{Channel: evt.Item.Channel, Timestamp: evt.Item.Timestamp, User: evt.User, Reaction: evt.Reaction})
+	case *slackevents.ReactionRemovedEvent:
+		user.forwardReactionRemoved(&SlackReaction{Channel: evt.Item.Channel, Timestamp: evt.Item.Timestamp, User: evt.User, Reaction: evt.Reaction})
+	case *slack.UserTypingEvent:
+		if user.BridgeTyping {
+			if portal := user.bridge.GetPortalByID(evt.Channel); portal != nil {
+				portal.HandleSlackTyping(user, evt)
+			}
+		}
+	case *slack.ChannelJoinedEvent:
+		if portal := user.bridge.GetPortalByID(evt.Channel.ID); portal != nil {
+			portal.Sync(user, &evt.Channel)
+		}
+	case *slack.TeamJoinEvent:
+		if puppet := user.bridge.GetPuppetByID(evt.User.ID); puppet != nil {
+			puppet.Sync(user, &evt.User)
+		}
+	case *slack.PresenceChangeEvent:
+		if user.shouldBridgePresence() {
+			if puppet := user.bridge.GetPuppetByID(evt.User); puppet != nil {
+				puppet.HandleSlackPresence(user, evt.Presence)
+			}
+		}
+	default:
+		user.log.Debugfln("Unhandled Slack event of type %T", evt)
+	}
+}
+
+func (user *User) forwardMessage(msg *SlackMessage) {
+	if portal := user.bridge.GetPortalByID(msg.Channel); portal != nil {
+		portal.HandleSlackMessage(user, msg)
+	}
+}
+
+func (user *User) forwardReactionAdded(reaction *SlackReaction) {
+	if portal := user.bridge.GetPortalByID(reaction.Channel); portal != nil {
+		portal.HandleSlackReactionAdded(user, reaction)
+	}
+}
+
+func (user *User) forwardReactionRemoved(reaction *SlackReaction) {
+	if portal := user.bridge.GetPortalByID(reaction.Channel); portal != nil {
+		portal.HandleSlackReactionRemoved(user, reaction)
+	}
+}
+
+// handleInvalidAuth cleans up the session the same way Logout does and lets
+// the user know in their management room so they can log in again.
+func (user *User) handleInvalidAuth() {
+	if err := user.Logout(); err != nil && !errors.Is(err, ErrNotLoggedIn) {
+		user.log.Errorln("Failed to clean up after invalid auth:", err)
+	}
+
+	if user.ManagementRoom != "" {
+		_, _ = user.bridge.Bot.SendNotice(user.ManagementRoom, "Your Slack session expired or was revoked, please log in again.")
+	}
+}